@@ -0,0 +1,240 @@
+// Copyright 2019, 2020 OCI Contributors
+// Copyright 2017 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package digest
+
+import (
+	"crypto"
+	"fmt"
+	"hash"
+	"io"
+	"regexp"
+	"strings"
+
+	_ "crypto/sha256" // ensure sha256 algorithm is registered
+	_ "crypto/sha512" // ensure sha384, sha512 algorithms are registered
+)
+
+// Algorithm identifies and implementation of a digester by an identifier.
+// Note the that this defines both the hashing algorithm used and the
+// serialization method to convert the hash sum to a string.
+type Algorithm string
+
+// supported digest types
+const (
+	SHA256 Algorithm = "sha256" // sha256 with hex encoding
+	SHA384 Algorithm = "sha384" // sha384 with hex encoding
+	SHA512 Algorithm = "sha512" // sha512 with hex encoding
+
+	// Canonical is the primary digest algorithm used with the distribution
+	// project. Other digests may be used but this one is the primary storage
+	// digest.
+	Canonical = SHA256
+)
+
+var (
+	// algorithms maps values to hash.Hash implementations. Other algorithms
+	// may be available but they cannot be calculated by the digest package.
+	algorithms = map[Algorithm]crypto.Hash{
+		SHA256: crypto.SHA256,
+		SHA384: crypto.SHA384,
+		SHA512: crypto.SHA512,
+	}
+
+	// anchoredEncodedRegexps contains anchored regular expressions for hex-encoded digests.
+	anchoredEncodedRegexps = map[Algorithm]*regexp.Regexp{
+		SHA256: regexp.MustCompile(`^[a-f0-9]{64}$`),
+		SHA384: regexp.MustCompile(`^[a-f0-9]{96}$`),
+		SHA512: regexp.MustCompile(`^[a-f0-9]{128}$`),
+	}
+
+	// algorithmRegexp validates that an algorithm identifier only contains
+	// lowercase alphanumeric segments separated by a single `+`, `.`, `_`
+	// or `-`.
+	algorithmRegexp = regexp.MustCompile(`^[a-z0-9]+(?:[.+_-][a-z0-9]+)*$`)
+)
+
+// Available returns true if the digest type is available for use. If this
+// returns false, Hash will panic, and so will Digester unless a ResumableHash
+// is registered for the algorithm.
+func (a Algorithm) Available() bool {
+	if transform, base, ok := a.splitTransform(); ok {
+		_, ok := transforms[transform]
+		return ok && base.Available()
+	}
+
+	h, ok := algorithms[a]
+	if !ok {
+		return false
+	}
+	return h.Available()
+}
+
+func (a Algorithm) String() string {
+	return string(a)
+}
+
+// Size returns number of bytes encoded in a hexadecimal encoded digest.
+func (a Algorithm) Size() int {
+	if transform, base, ok := a.splitTransform(); ok {
+		if _, ok := transforms[transform]; !ok {
+			return 0
+		}
+		return base.Size()
+	}
+
+	h, ok := algorithms[a]
+	if !ok {
+		return 0
+	}
+	return h.Size()
+}
+
+// splitTransform splits a compound "<transform>+<hash>" algorithm
+// identifier, such as "tarsum.v1+sha256", into its transform name and base
+// hash Algorithm. ok is false if a does not contain a transform, including
+// when a itself is directly registered (via RegisterAlgorithm) despite
+// containing a "+".
+func (a Algorithm) splitTransform() (transform string, base Algorithm, ok bool) {
+	if _, ok := algorithms[a]; ok {
+		return "", a, false
+	}
+
+	i := strings.LastIndex(string(a), "+")
+	if i < 0 {
+		return "", a, false
+	}
+	return string(a)[:i], Algorithm(a[i+1:]), true
+}
+
+// Set implements flag.Value.
+func (a *Algorithm) Set(value string) error {
+	if value == "" {
+		*a = Canonical
+	} else {
+		// just store the value, validate later
+		*a = Algorithm(value)
+	}
+
+	if !a.Available() {
+		return ErrDigestUnsupported
+	}
+
+	return nil
+}
+
+// Digester returns a new digester for the specified algorithm. If the
+// algorithm is not available and has no ResumableHash registered, Digester
+// panics, as Hash does. This can be checked by calling Available before
+// calling Digester.
+//
+// If a is Resumable, the returned Digester can be type-asserted to a
+// ResumableDigester.
+func (a Algorithm) Digester() Digester {
+	if newHash, ok := resumableHashes[a]; ok {
+		return &resumableDigester{alg: a, ResumableHash: newHash()}
+	}
+
+	return &digester{
+		alg:  a,
+		hash: a.Hash(),
+	}
+}
+
+// Hash returns a new hash as used by the algorithm. If not available, the
+// method will panic. Check Algorithm.Available() before calling.
+//
+// For a compound "<transform>+<hash>" algorithm, the hash wraps the base
+// hash with the registered transform (see RegisterTransform).
+func (a Algorithm) Hash() hash.Hash {
+	if !a.Available() {
+		// NOTE: a missing hash is usually a programming error that must be
+		// resolved at compile time. We don't import the hash implementations
+		// in this package to allow callers to choose their own (ie cgo vs
+		// non-cgo).
+		panic(fmt.Sprintf("%v not available (make sure it is registered and imported)", a))
+	}
+
+	if transform, base, ok := a.splitTransform(); ok {
+		return transforms[transform](base.Hash())
+	}
+
+	return algorithms[a].New()
+}
+
+// Encode encodes the raw bytes of a digest, typically from a hash.Hash, into
+// the encoded portion of the digest.
+func (a Algorithm) Encode(d []byte) string {
+	return fmt.Sprintf("%x", d)
+}
+
+// FromReader returns the digest of the reader using the algorithm.
+func (a Algorithm) FromReader(rd io.Reader) (Digest, error) {
+	digester := a.Digester()
+
+	if _, err := io.Copy(digester.Hash(), rd); err != nil {
+		return "", err
+	}
+
+	return digester.Digest(), nil
+}
+
+// FromBytes digests the input and returns a Digest.
+func (a Algorithm) FromBytes(p []byte) Digest {
+	digester := a.Digester()
+
+	if _, err := digester.Hash().Write(p); err != nil {
+		// Writes to a Hash can never fail. Something is seriously wrong if it happens.
+		panic("write to hash function returned error: " + err.Error())
+	}
+
+	return digester.Digest()
+}
+
+// FromString digests the string input and returns a Digest.
+func (a Algorithm) FromString(s string) Digest {
+	return a.FromBytes([]byte(s))
+}
+
+// Validate validates the encoded portion string.
+func (a Algorithm) Validate(encoded string) error {
+	if transform, base, ok := a.splitTransform(); ok {
+		if _, ok := transforms[transform]; !ok {
+			return ErrDigestUnsupported
+		}
+		return base.Validate(encoded)
+	}
+
+	r, ok := anchoredEncodedRegexps[a]
+	if !ok {
+		return ErrDigestUnsupported
+	}
+	// Digests must always be hex-encoded, ensuring that their hex portion
+	// is size appropriate for the algorithm.
+	if !r.MatchString(encoded) {
+		return ErrDigestInvalidLength
+	}
+	return nil
+}
+
+// RegisterAlgorithm registers the hash function for the algorithm, panicking
+// if the algorithm identifier is malformed.
+func RegisterAlgorithm(alg Algorithm, h crypto.Hash) {
+	if !algorithmRegexp.MatchString(alg.String()) {
+		panic(fmt.Sprintf("invalid algorithm name: %q", alg))
+	}
+
+	algorithms[alg] = h
+}
@@ -0,0 +1,187 @@
+// Copyright 2019, 2020 OCI Contributors
+// Copyright 2017 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command digest computes and verifies content digests using the
+// algorithms registered with the digest package.
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/dweomer/go-digest"
+)
+
+func main() {
+	var (
+		alg   = digest.Canonical
+		check bool
+	)
+
+	flag.Var(&alg, "a", "digest algorithm to use")
+	flag.Var(&alg, "algorithm", "digest algorithm to use")
+	flag.BoolVar(&check, "c", false, "read a list of digests and filenames and verify each one")
+	flag.BoolVar(&check, "check", false, "read a list of digests and filenames and verify each one")
+	flag.Usage = usage
+	flag.Parse()
+
+	var err error
+	if check {
+		err = runCheck(flag.Args())
+	} else {
+		err = runSum(alg, flag.Args())
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "digest:", err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, `Usage: %[1]s [-a algorithm] [file ...]
+       %[1]s -c [file ...]
+
+Compute the digest of each file (or standard input, when no file is given
+or file is "-"), printing "alg:hex  filename" lines. With -c, read such
+lines instead and verify each named file against its recorded digest.
+
+Flags:
+`, os.Args[0])
+	flag.PrintDefaults()
+}
+
+// runSum computes alg's digest of each named file and prints it as an
+// "alg:hex  filename" line.
+func runSum(alg digest.Algorithm, files []string) error {
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+
+	var failed bool
+	for _, file := range files {
+		dgst, err := digestFile(alg, file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "digest: %s: %v\n", file, err)
+			failed = true
+			continue
+		}
+		fmt.Printf("%s  %s\n", dgst, file)
+	}
+
+	if failed {
+		return fmt.Errorf("failed to digest one or more files")
+	}
+	return nil
+}
+
+func digestFile(alg digest.Algorithm, file string) (digest.Digest, error) {
+	rd, err := open(file)
+	if err != nil {
+		return "", err
+	}
+	defer rd.Close()
+
+	return alg.FromReader(rd)
+}
+
+// runCheck reads the "alg:hex  filename" lines produced by runSum and
+// verifies each named file against its recorded digest.
+func runCheck(files []string) error {
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+
+	var failed bool
+	for _, file := range files {
+		if err := checkFile(file); err != nil {
+			fmt.Fprintf(os.Stderr, "digest: %s: %v\n", file, err)
+			failed = true
+		}
+	}
+
+	if failed {
+		return fmt.Errorf("one or more checks failed")
+	}
+	return nil
+}
+
+func checkFile(listFile string) error {
+	rd, err := open(listFile)
+	if err != nil {
+		return err
+	}
+	defer rd.Close()
+
+	var failed bool
+	scanner := bufio.NewScanner(rd)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+
+		encoded, target, ok := strings.Cut(line, "  ")
+		if !ok {
+			return fmt.Errorf("malformed line: %q", line)
+		}
+
+		if err := verifyFile(digest.Digest(encoded), target); err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", target, err)
+			failed = true
+			continue
+		}
+		fmt.Printf("%s: OK\n", target)
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	if failed {
+		return fmt.Errorf("one or more checks failed")
+	}
+	return nil
+}
+
+func verifyFile(dgst digest.Digest, file string) error {
+	if err := dgst.Validate(); err != nil {
+		return err
+	}
+
+	rd, err := open(file)
+	if err != nil {
+		return err
+	}
+	defer rd.Close()
+
+	verifier := dgst.Verifier()
+	if _, err := io.Copy(verifier, rd); err != nil {
+		return err
+	}
+	if !verifier.Verified() {
+		return fmt.Errorf("FAILED")
+	}
+	return nil
+}
+
+func open(file string) (io.ReadCloser, error) {
+	if file == "-" {
+		return io.NopCloser(os.Stdin), nil
+	}
+	return os.Open(file)
+}
@@ -0,0 +1,168 @@
+// Copyright 2019, 2020 OCI Contributors
+// Copyright 2017 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dweomer/go-digest"
+)
+
+// captureStdout redirects os.Stdout for the duration of f and returns
+// everything written to it.
+func captureStdout(t *testing.T, f func()) string {
+	t.Helper()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	orig := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = orig }()
+
+	f()
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	out, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(out)
+}
+
+// withStdin redirects os.Stdin to content for the duration of f.
+func withStdin(t *testing.T, content string, f func()) {
+	t.Helper()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	orig := os.Stdin
+	os.Stdin = r
+	defer func() { os.Stdin = orig }()
+
+	go func() {
+		io.WriteString(w, content)
+		w.Close()
+	}()
+
+	f()
+}
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRunSumFile(t *testing.T) {
+	dir := t.TempDir()
+	path := writeFile(t, dir, "hello.txt", "hello world")
+
+	var err error
+	out := captureStdout(t, func() {
+		err = runSum(digest.Canonical, []string{path})
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := digest.FromString("hello world").String() + "  " + path + "\n"
+	if out != want {
+		t.Fatalf("output = %q, want %q", out, want)
+	}
+}
+
+func TestRunSumStdin(t *testing.T) {
+	var err error
+	var out string
+	withStdin(t, "hello world", func() {
+		out = captureStdout(t, func() {
+			err = runSum(digest.Canonical, nil)
+		})
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := digest.FromString("hello world").String() + "  -\n"
+	if out != want {
+		t.Fatalf("output = %q, want %q", out, want)
+	}
+}
+
+func TestRunSumMissingFile(t *testing.T) {
+	dir := t.TempDir()
+	if err := runSum(digest.Canonical, []string{filepath.Join(dir, "missing")}); err == nil {
+		t.Fatal("expected error digesting a missing file")
+	}
+}
+
+func TestCheckFilePass(t *testing.T) {
+	dir := t.TempDir()
+	target := writeFile(t, dir, "hello.txt", "hello world")
+	dgst := digest.FromString("hello world")
+	list := writeFile(t, dir, "sums.txt", dgst.String()+"  "+target+"\n")
+
+	var err error
+	out := captureStdout(t, func() {
+		err = checkFile(list)
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := target + ": OK\n"; out != want {
+		t.Fatalf("output = %q, want %q", out, want)
+	}
+}
+
+func TestCheckFileFail(t *testing.T) {
+	dir := t.TempDir()
+	target := writeFile(t, dir, "hello.txt", "hello world")
+	wrong := digest.FromString("goodbye world")
+	list := writeFile(t, dir, "sums.txt", wrong.String()+"  "+target+"\n")
+
+	if err := checkFile(list); err == nil {
+		t.Fatal("expected error from a mismatched digest")
+	}
+}
+
+func TestCheckFileMalformedLine(t *testing.T) {
+	dir := t.TempDir()
+	list := writeFile(t, dir, "sums.txt", "sha256:deadbeef not-two-spaces\n")
+
+	err := checkFile(list)
+	if err == nil {
+		t.Fatal("expected error for malformed line")
+	}
+	if !strings.Contains(err.Error(), "malformed line") {
+		t.Fatalf("error = %v, want it to mention a malformed line", err)
+	}
+}
@@ -77,6 +77,12 @@ var (
 
 	// ErrDigestUnsupported returned when the digest algorithm is unsupported.
 	ErrDigestUnsupported = errors.New("unsupported digest algorithm")
+
+	// ErrDigestSizeMismatch returned from Write by a Verifier created with
+	// Digest.SizeVerifier when the write would take the number of bytes
+	// written past the expected size. Writing fewer bytes than expected
+	// returns no error; Verified then reports false.
+	ErrDigestSizeMismatch = errors.New("digest size mismatch")
 )
 
 // Parse parses s and returns the validated digest object. An error will
@@ -137,6 +143,23 @@ func (d Digest) Verifier() Verifier {
 	}
 }
 
+// SizeVerifier returns a Verifier like Verifier, but one that also knows the
+// expected content length. It returns an error from Write as soon as more
+// than size bytes are written, and once exactly size bytes have been
+// written it eagerly computes and caches the result of Verified, so that a
+// single-request upload can be verified as soon as its declared
+// Content-Length is reached without waiting for the caller to call Verified
+// separately. If the digest is invalid, the method will panic.
+func (d Digest) SizeVerifier(size int64) Verifier {
+	return &sizeVerifier{
+		hashVerifier: hashVerifier{
+			hash:   d.Algorithm().Hash(),
+			digest: d,
+		},
+		size: size,
+	}
+}
+
 // Encoded returns the encoded portion of the digest. It panics if the
 // underlying digest is not in a valid format.
 func (d Digest) Encoded() string {
@@ -0,0 +1,191 @@
+// Copyright 2019, 2020 OCI Contributors
+// Copyright 2017 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package digest
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ShortCodeTableSize is the default minimum length, in encoded characters,
+// that DigestSet.Lookup will accept for a short identifier. It may be
+// changed with NewDigestSetWithShortCodeLength.
+const ShortCodeTableSize = 7
+
+var (
+	// ErrDigestNotFound is returned when no entry in a DigestSet matches a
+	// short identifier.
+	ErrDigestNotFound = errors.New("digest not found")
+
+	// ErrDigestAmbiguous is returned when a short identifier matches more
+	// than one entry in a DigestSet.
+	ErrDigestAmbiguous = errors.New("ambiguous digest string")
+
+	// ErrDigestShortCodeTooShort is returned when a short identifier is
+	// shorter than the minimum length configured on the DigestSet.
+	ErrDigestShortCodeTooShort = errors.New("short digest string too short")
+)
+
+// digestEntries is a slice of Digest values kept sorted by their Encoded()
+// form so that prefix lookups can be done with a binary search.
+type digestEntries []Digest
+
+func (d digestEntries) Len() int           { return len(d) }
+func (d digestEntries) Less(i, j int) bool { return d[i].Encoded() < d[j].Encoded() }
+func (d digestEntries) Swap(i, j int)      { d[i], d[j] = d[j], d[i] }
+
+// DigestSet indexes a collection of Digest values, keyed by algorithm and
+// sorted by their encoded form, so that an ambiguous short identifier (for
+// example "sha256:7173b8" or just "7173b8") can be resolved back to the full
+// Digest it names. This is analogous to Git's short-SHA lookup.
+//
+// The zero value is not usable; construct one with NewDigestSet or
+// NewDigestSetWithShortCodeLength. A DigestSet is safe for concurrent use.
+type DigestSet struct {
+	mutex     sync.RWMutex
+	minLength int
+	byAlg     map[Algorithm]digestEntries
+}
+
+// NewDigestSet returns an empty DigestSet that requires at least
+// ShortCodeTableSize encoded characters to resolve a short identifier.
+func NewDigestSet() *DigestSet {
+	return NewDigestSetWithShortCodeLength(ShortCodeTableSize)
+}
+
+// NewDigestSetWithShortCodeLength returns an empty DigestSet that requires
+// at least minLength encoded characters to resolve a short identifier.
+func NewDigestSetWithShortCodeLength(minLength int) *DigestSet {
+	return &DigestSet{
+		minLength: minLength,
+		byAlg:     make(map[Algorithm]digestEntries),
+	}
+}
+
+// Add inserts dgst into the set. It returns an error if dgst is not a valid
+// digest. Adding a digest that is already present is a no-op.
+func (dst *DigestSet) Add(dgst Digest) error {
+	if err := dgst.Validate(); err != nil {
+		return err
+	}
+
+	dst.mutex.Lock()
+	defer dst.mutex.Unlock()
+
+	alg := dgst.Algorithm()
+	entries := dst.byAlg[alg]
+
+	i := sort.Search(len(entries), func(i int) bool {
+		return entries[i].Encoded() >= dgst.Encoded()
+	})
+	if i < len(entries) && entries[i] == dgst {
+		return nil
+	}
+
+	entries = append(entries, "")
+	copy(entries[i+1:], entries[i:])
+	entries[i] = dgst
+	dst.byAlg[alg] = entries
+
+	return nil
+}
+
+// Remove deletes dgst from the set. It returns ErrDigestNotFound if dgst is
+// not present.
+func (dst *DigestSet) Remove(dgst Digest) error {
+	if err := dgst.Validate(); err != nil {
+		return err
+	}
+
+	dst.mutex.Lock()
+	defer dst.mutex.Unlock()
+
+	alg := dgst.Algorithm()
+	entries := dst.byAlg[alg]
+
+	i := sort.Search(len(entries), func(i int) bool {
+		return entries[i].Encoded() >= dgst.Encoded()
+	})
+	if i >= len(entries) || entries[i] != dgst {
+		return ErrDigestNotFound
+	}
+
+	dst.byAlg[alg] = append(entries[:i], entries[i+1:]...)
+	return nil
+}
+
+// Lookup resolves shortID to the single Digest it unambiguously identifies.
+// shortID may be a full or partial encoded digest, optionally prefixed with
+// "alg:" to restrict the search to that algorithm; without the prefix, every
+// registered algorithm is searched. It returns ErrDigestShortCodeTooShort if
+// shortID's encoded portion is shorter than the set's configured minimum,
+// ErrDigestNotFound if nothing matches, and ErrDigestAmbiguous if more than
+// one digest matches.
+func (dst *DigestSet) Lookup(shortID string) (Digest, error) {
+	if shortID == "" {
+		return "", ErrDigestNotFound
+	}
+
+	alg, encoded, hasAlg := strings.Cut(shortID, ":")
+	if !hasAlg {
+		encoded = alg
+		alg = ""
+	}
+
+	if len(encoded) < dst.minLength {
+		return "", ErrDigestShortCodeTooShort
+	}
+
+	dst.mutex.RLock()
+	defer dst.mutex.RUnlock()
+
+	var found Digest
+	for a, entries := range dst.byAlg {
+		if hasAlg && string(a) != alg {
+			continue
+		}
+
+		i := sort.Search(len(entries), func(i int) bool {
+			return entries[i].Encoded() >= encoded
+		})
+		for ; i < len(entries) && strings.HasPrefix(entries[i].Encoded(), encoded); i++ {
+			if found != "" && found != entries[i] {
+				return "", ErrDigestAmbiguous
+			}
+			found = entries[i]
+		}
+	}
+
+	if found == "" {
+		return "", ErrDigestNotFound
+	}
+
+	return found, nil
+}
+
+// All returns every Digest currently in the set, in no particular order.
+func (dst *DigestSet) All() []Digest {
+	dst.mutex.RLock()
+	defer dst.mutex.RUnlock()
+
+	var digests []Digest
+	for _, entries := range dst.byAlg {
+		digests = append(digests, entries...)
+	}
+	return digests
+}
@@ -0,0 +1,139 @@
+// Copyright 2019, 2020 OCI Contributors
+// Copyright 2017 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package digest
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDigestSetAddAndLookup(t *testing.T) {
+	set := NewDigestSet()
+
+	dgst := FromString("hello world")
+	if err := set.Add(dgst); err != nil {
+		t.Fatalf("unexpected error adding digest: %v", err)
+	}
+
+	// re-adding is a no-op, not an error
+	if err := set.Add(dgst); err != nil {
+		t.Fatalf("unexpected error re-adding digest: %v", err)
+	}
+
+	for _, short := range []string{
+		dgst.String(),
+		dgst.Encoded(),
+		dgst.Encoded()[:ShortCodeTableSize],
+		string(dgst.Algorithm()) + ":" + dgst.Encoded()[:ShortCodeTableSize],
+	} {
+		found, err := set.Lookup(short)
+		if err != nil {
+			t.Fatalf("Lookup(%q): unexpected error: %v", short, err)
+		}
+		if found != dgst {
+			t.Fatalf("Lookup(%q) = %v, want %v", short, found, dgst)
+		}
+	}
+}
+
+func TestDigestSetLookupErrors(t *testing.T) {
+	set := NewDigestSet()
+	dgst1 := FromString("hello world")
+	dgst2 := FromString("goodbye world")
+	if err := set.Add(dgst1); err != nil {
+		t.Fatal(err)
+	}
+	if err := set.Add(dgst2); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := set.Lookup(""); !errors.Is(err, ErrDigestNotFound) {
+		t.Fatalf("Lookup(\"\") error = %v, want %v", err, ErrDigestNotFound)
+	}
+
+	if _, err := set.Lookup("deadbeef"); !errors.Is(err, ErrDigestNotFound) {
+		t.Fatalf("Lookup of unknown digest error = %v, want %v", err, ErrDigestNotFound)
+	}
+
+	if _, err := set.Lookup("abc"); !errors.Is(err, ErrDigestShortCodeTooShort) {
+		t.Fatalf("Lookup of too-short code error = %v, want %v", err, ErrDigestShortCodeTooShort)
+	}
+
+	// "38" and "64" were chosen because their sha256 digests share a
+	// leading "a", which makes a single-character lookup ambiguous.
+	set2 := NewDigestSetWithShortCodeLength(1)
+	ambiguous1 := FromString("38")
+	ambiguous2 := FromString("64")
+	if err := set2.Add(ambiguous1); err != nil {
+		t.Fatal(err)
+	}
+	if err := set2.Add(ambiguous2); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := set2.Lookup("a"); !errors.Is(err, ErrDigestAmbiguous) {
+		t.Fatalf(`Lookup("a") error = %v, want %v`, err, ErrDigestAmbiguous)
+	}
+}
+
+func TestDigestSetRemove(t *testing.T) {
+	set := NewDigestSet()
+	dgst := FromString("hello world")
+
+	if err := set.Remove(dgst); !errors.Is(err, ErrDigestNotFound) {
+		t.Fatalf("Remove of absent digest error = %v, want %v", err, ErrDigestNotFound)
+	}
+
+	if err := set.Add(dgst); err != nil {
+		t.Fatal(err)
+	}
+	if err := set.Remove(dgst); err != nil {
+		t.Fatalf("unexpected error removing digest: %v", err)
+	}
+	if _, err := set.Lookup(dgst.String()); !errors.Is(err, ErrDigestNotFound) {
+		t.Fatalf("Lookup after remove error = %v, want %v", err, ErrDigestNotFound)
+	}
+}
+
+func TestDigestSetAddInvalid(t *testing.T) {
+	set := NewDigestSet()
+	if err := set.Add(""); err == nil {
+		t.Fatal("expected error adding empty digest")
+	}
+}
+
+func TestDigestSetAll(t *testing.T) {
+	set := NewDigestSet()
+	want := map[Digest]bool{
+		FromString("a"): true,
+		FromString("b"): true,
+		FromString("c"): true,
+	}
+	for dgst := range want {
+		if err := set.Add(dgst); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got := set.All()
+	if len(got) != len(want) {
+		t.Fatalf("All() returned %d digests, want %d", len(got), len(want))
+	}
+	for _, dgst := range got {
+		if !want[dgst] {
+			t.Fatalf("All() returned unexpected digest %v", dgst)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright 2019, 2020 OCI Contributors
+// Copyright 2017 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package digest
+
+import (
+	"fmt"
+	"hash"
+)
+
+// ResumableHash is a hash.Hash that can snapshot and later restore its
+// internal state, allowing a caller uploading a large blob across multiple
+// chunked requests to persist the running hash between requests and resume
+// hashing without re-reading the bytes already written.
+type ResumableHash interface {
+	hash.Hash
+
+	// State returns a snapshot of the hash's internal state.
+	State() ([]byte, error)
+
+	// Restore replaces the hash's internal state with a snapshot
+	// previously returned by State. The hash must not have been written to
+	// since it was created.
+	Restore(state []byte) error
+
+	// Len returns the number of bytes written to the hash so far.
+	Len() int64
+}
+
+// ResumableDigester is a Digester whose underlying hash implementation
+// supports checkpoint/resume via ResumableHash. A Digester returned by
+// Algorithm.Digester can be type-asserted to ResumableDigester when
+// Algorithm.Resumable reports true.
+type ResumableDigester interface {
+	Digester
+
+	State() ([]byte, error)
+	Restore(state []byte) error
+	Len() int64
+}
+
+// resumableHashes holds the ResumableHash constructors registered with
+// RegisterResumableHash, keyed by algorithm.
+var resumableHashes = map[Algorithm]func() ResumableHash{}
+
+// RegisterResumableHash registers a ResumableHash constructor for alg,
+// alongside the crypto.Hash registered with RegisterAlgorithm. Once
+// registered, Algorithm.Digester returns a ResumableDigester for alg.
+func RegisterResumableHash(alg Algorithm, h func() ResumableHash) {
+	if !algorithmRegexp.MatchString(alg.String()) {
+		panic(fmt.Sprintf("invalid algorithm name: %q", alg))
+	}
+
+	resumableHashes[alg] = h
+}
+
+// Resumable reports whether alg has a ResumableHash registered, so that
+// callers can fall back to a plain Digester when it does not.
+func (a Algorithm) Resumable() bool {
+	_, ok := resumableHashes[a]
+	return ok
+}
+
+// resumableDigester adapts a ResumableHash into a ResumableDigester.
+type resumableDigester struct {
+	alg Algorithm
+	ResumableHash
+}
+
+func (d *resumableDigester) Hash() hash.Hash {
+	return d.ResumableHash
+}
+
+func (d *resumableDigester) Digest() Digest {
+	return NewDigest(d.alg, d.ResumableHash)
+}
@@ -0,0 +1,106 @@
+// Copyright 2019, 2020 OCI Contributors
+// Copyright 2017 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package digest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding"
+	"encoding/gob"
+	"errors"
+	"hash"
+)
+
+func init() {
+	RegisterResumableHash(SHA256, newMarshalHash(sha256.New))
+	RegisterResumableHash(SHA384, newMarshalHash(sha512.New384))
+	RegisterResumableHash(SHA512, newMarshalHash(sha512.New))
+}
+
+// marshalHashState is the gob-encoded snapshot produced by marshalHash.State.
+// The stdlib hash implementations encode their own internal state (h, len,
+// and the partial-block buffer) via encoding.BinaryMarshaler; Len is tracked
+// here because hash.Hash itself has no way to report bytes written so far.
+type marshalHashState struct {
+	Len  int64
+	Hash []byte
+}
+
+// marshalHash adapts any hash.Hash that also implements
+// encoding.BinaryMarshaler and encoding.BinaryUnmarshaler, as the stdlib
+// SHA-2 implementations do, into a ResumableHash.
+type marshalHash struct {
+	hash.Hash
+	len int64
+}
+
+func newMarshalHash(newHash func() hash.Hash) func() ResumableHash {
+	return func() ResumableHash {
+		return &marshalHash{Hash: newHash()}
+	}
+}
+
+func (m *marshalHash) Write(p []byte) (n int, err error) {
+	n, err = m.Hash.Write(p)
+	m.len += int64(n)
+	return
+}
+
+func (m *marshalHash) Reset() {
+	m.Hash.Reset()
+	m.len = 0
+}
+
+func (m *marshalHash) Len() int64 {
+	return m.len
+}
+
+func (m *marshalHash) State() ([]byte, error) {
+	marshaler, ok := m.Hash.(encoding.BinaryMarshaler)
+	if !ok {
+		return nil, errors.New("digest: hash implementation does not support state snapshotting")
+	}
+
+	hashState, err := marshaler.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(marshalHashState{Len: m.len, Hash: hashState}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (m *marshalHash) Restore(state []byte) error {
+	var s marshalHashState
+	if err := gob.NewDecoder(bytes.NewReader(state)).Decode(&s); err != nil {
+		return err
+	}
+
+	unmarshaler, ok := m.Hash.(encoding.BinaryUnmarshaler)
+	if !ok {
+		return errors.New("digest: hash implementation does not support state restoration")
+	}
+	if err := unmarshaler.UnmarshalBinary(s.Hash); err != nil {
+		return err
+	}
+
+	m.len = s.Len
+	return nil
+}
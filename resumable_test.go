@@ -0,0 +1,81 @@
+// Copyright 2019, 2020 OCI Contributors
+// Copyright 2017 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package digest
+
+import (
+	"crypto/rand"
+	"testing"
+)
+
+func TestResumableDigesterCheckpoint(t *testing.T) {
+	p := make([]byte, 4<<20)
+	if _, err := rand.Read(p); err != nil {
+		t.Fatal(err)
+	}
+
+	for alg := range resumableHashes {
+		t.Run(string(alg), func(t *testing.T) {
+			expected := alg.FromBytes(p)
+
+			for _, offset := range []int{0, 1, 1023, 1 << 16, len(p) - 1, len(p)} {
+				digester, ok := alg.Digester().(ResumableDigester)
+				if !ok {
+					t.Fatalf("%s: Digester() is not a ResumableDigester", alg)
+				}
+
+				if _, err := digester.Hash().Write(p[:offset]); err != nil {
+					t.Fatal(err)
+				}
+				if digester.Len() != int64(offset) {
+					t.Fatalf("Len() = %d, want %d", digester.Len(), offset)
+				}
+
+				state, err := digester.State()
+				if err != nil {
+					t.Fatalf("State(): %v", err)
+				}
+
+				resumed, ok := alg.Digester().(ResumableDigester)
+				if !ok {
+					t.Fatalf("%s: Digester() is not a ResumableDigester", alg)
+				}
+				if err := resumed.Restore(state); err != nil {
+					t.Fatalf("Restore(): %v", err)
+				}
+				if resumed.Len() != int64(offset) {
+					t.Fatalf("after Restore, Len() = %d, want %d", resumed.Len(), offset)
+				}
+
+				if _, err := resumed.Hash().Write(p[offset:]); err != nil {
+					t.Fatal(err)
+				}
+
+				if dgst := resumed.Digest(); dgst != expected {
+					t.Fatalf("offset %d: digest = %v, want %v", offset, dgst, expected)
+				}
+			}
+		})
+	}
+}
+
+func TestAlgorithmResumable(t *testing.T) {
+	if !SHA256.Resumable() {
+		t.Fatal("SHA256 should be resumable")
+	}
+	if Algorithm("bogus-algorithm").Resumable() {
+		t.Fatal("unregistered algorithm should not be resumable")
+	}
+}
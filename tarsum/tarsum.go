@@ -0,0 +1,129 @@
+// Copyright 2019, 2020 OCI Contributors
+// Copyright 2017 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tarsum provides a reference digest.RegisterTransform
+// implementation, registered as "tarsum.v1", that digests the regular-file
+// entries of a tar stream independently of their order and of all metadata
+// other than each entry's name and mode (timestamps, ownership, and so on).
+//
+// Importing this package for its side effect registers the transform, after
+// which digest.Algorithm("tarsum.v1+sha256") (or any other registered base
+// hash) becomes usable:
+//
+//	import _ "github.com/dweomer/go-digest/tarsum"
+//
+//	dgst, err := digest.Algorithm("tarsum.v1+sha256").FromReader(tarball)
+//
+// This is a compact implementation of the tarsum convention and is not
+// bit-for-bit compatible with the historical Docker tarsum implementation
+// it is modeled on.
+package tarsum
+
+import (
+	"archive/tar"
+	"bytes"
+	"fmt"
+	"hash"
+	"io"
+	"sort"
+
+	"github.com/dweomer/go-digest"
+)
+
+func init() {
+	digest.RegisterTransform("tarsum.v1", New)
+}
+
+// New wraps inner with the tarsum.v1 transform. The returned hash.Hash
+// buffers everything written to it, since a tar stream must be read in
+// full to normalize the order of its entries; Sum parses that buffer as a
+// tar stream and feeds inner a canonical representation of it.
+func New(inner hash.Hash) hash.Hash {
+	return &tarsum{inner: inner}
+}
+
+type tarsum struct {
+	inner hash.Hash
+	buf   bytes.Buffer
+	sum   []byte
+}
+
+func (t *tarsum) Write(p []byte) (int, error) {
+	t.sum = nil
+	return t.buf.Write(p)
+}
+
+func (t *tarsum) Sum(b []byte) []byte {
+	if t.sum == nil {
+		t.sum = t.compute()
+	}
+	return append(b, t.sum...)
+}
+
+func (t *tarsum) Reset() {
+	t.buf.Reset()
+	t.sum = nil
+}
+
+func (t *tarsum) Size() int      { return t.inner.Size() }
+func (t *tarsum) BlockSize() int { return t.inner.BlockSize() }
+
+// compute normalizes each regular-file entry in the buffered tar stream to
+// a "name:%s\nmode:%o\n"-prefixed digest of its content, sorts the results
+// by name, and hashes the sorted list with inner. A tar stream that fails
+// to parse is hashed as-is, so callers always get a stable result.
+func (t *tarsum) compute() []byte {
+	type file struct {
+		name string
+		sum  []byte
+	}
+
+	var files []file
+	tr := tar.NewReader(bytes.NewReader(t.buf.Bytes()))
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return t.sumRaw()
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		t.inner.Reset()
+		fmt.Fprintf(t.inner, "name:%s\nmode:%o\n", hdr.Name, hdr.Mode)
+		if _, err := io.Copy(t.inner, tr); err != nil {
+			return t.sumRaw()
+		}
+
+		files = append(files, file{name: hdr.Name, sum: t.inner.Sum(nil)})
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].name < files[j].name })
+
+	t.inner.Reset()
+	for _, f := range files {
+		t.inner.Write(f.sum)
+	}
+	return t.inner.Sum(nil)
+}
+
+func (t *tarsum) sumRaw() []byte {
+	t.inner.Reset()
+	t.inner.Write(t.buf.Bytes())
+	return t.inner.Sum(nil)
+}
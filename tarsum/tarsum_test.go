@@ -0,0 +1,120 @@
+// Copyright 2019, 2020 OCI Contributors
+// Copyright 2017 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tarsum
+
+import (
+	"archive/tar"
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/dweomer/go-digest"
+)
+
+const alg = digest.Algorithm("tarsum.v1+sha256")
+
+func buildTar(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for name, body := range files {
+		hdr := &tar.Header{Name: name, Mode: 0o644, Size: int64(len(body))}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestTarsumRoundTrip(t *testing.T) {
+	if !alg.Available() {
+		t.Fatal("tarsum.v1+sha256 should be available once this package is imported")
+	}
+
+	tarball := buildTar(t, map[string]string{
+		"a.txt": "hello",
+		"b.txt": "world",
+	})
+
+	dgst, err := alg.FromReader(bytes.NewReader(tarball))
+	if err != nil {
+		t.Fatalf("FromReader: %v", err)
+	}
+
+	parsed, err := digest.Parse(dgst.String())
+	if err != nil {
+		t.Fatalf("Parse(%q): %v", dgst, err)
+	}
+	if parsed != dgst {
+		t.Fatalf("Parse roundtrip = %v, want %v", parsed, dgst)
+	}
+	if err := parsed.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if parsed.Algorithm() != alg {
+		t.Fatalf("Algorithm() = %v, want %v", parsed.Algorithm(), alg)
+	}
+}
+
+func TestTarsumIndependentOfEntryOrder(t *testing.T) {
+	forward := buildTar(t, map[string]string{"a": "1", "b": "2"})
+
+	var reordered bytes.Buffer
+	tw := tar.NewWriter(&reordered)
+	for _, name := range []string{"b", "a"} {
+		body := map[string]string{"a": "1", "b": "2"}[name]
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(body))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	dgst1, err := alg.FromReader(bytes.NewReader(forward))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dgst2, err := alg.FromReader(&reordered)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if dgst1 != dgst2 {
+		t.Fatalf("digest changed with entry order: %v != %v", dgst1, dgst2)
+	}
+}
+
+func TestTarsumMalformedStreamIsStable(t *testing.T) {
+	garbage := strings.Repeat("not a tar", 100)
+
+	dgst1 := alg.FromString(garbage)
+	dgst2 := alg.FromString(garbage)
+
+	if dgst1 != dgst2 {
+		t.Fatalf("digest of malformed stream is not stable: %v != %v", dgst1, dgst2)
+	}
+}
@@ -0,0 +1,50 @@
+// Copyright 2019, 2020 OCI Contributors
+// Copyright 2017 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package digest
+
+import (
+	"fmt"
+	"hash"
+	"regexp"
+)
+
+// transforms maps a transform name, such as "tarsum.v1", to a factory that
+// wraps an inner hash.Hash with the transform's preprocessing, keyed by the
+// part of a compound "<transform>+<hash>" Algorithm identifier that
+// precedes the "+".
+var transforms = map[string]func(inner hash.Hash) hash.Hash{}
+
+// transformRegexp validates that a transform name only contains lowercase
+// alphanumeric segments separated by a single `.`, `_` or `-`. Unlike
+// algorithmRegexp, `+` is excluded because it separates the transform from
+// the base hash algorithm in a compound identifier.
+var transformRegexp = regexp.MustCompile(`^[a-z0-9]+(?:[._-][a-z0-9]+)*$`)
+
+// RegisterTransform registers factory under name, allowing compound
+// algorithm identifiers of the form "name+hash" (for example
+// "tarsum.v1+sha256") to be used anywhere an Algorithm is accepted. factory
+// wraps the inner hash registered for the base algorithm with whatever
+// preprocessing the transform performs (tar normalization, canonical JSON,
+// and so on) before bytes reach it.
+//
+// RegisterTransform panics if name is not a valid transform identifier.
+func RegisterTransform(name string, factory func(inner hash.Hash) hash.Hash) {
+	if !transformRegexp.MatchString(name) {
+		panic(fmt.Sprintf("invalid transform name: %q", name))
+	}
+
+	transforms[name] = factory
+}
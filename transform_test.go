@@ -0,0 +1,90 @@
+// Copyright 2019, 2020 OCI Contributors
+// Copyright 2017 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package digest
+
+import (
+	"errors"
+	"hash"
+	"testing"
+)
+
+// upperHash is a toy transform that upper-cases its input before handing it
+// to the wrapped hash, used to exercise the compound "<transform>+<hash>"
+// grammar without depending on the tarsum sub-package.
+type upperHash struct {
+	hash.Hash
+}
+
+func (u upperHash) Write(p []byte) (int, error) {
+	up := make([]byte, len(p))
+	for i, b := range p {
+		if b >= 'a' && b <= 'z' {
+			b -= 'a' - 'A'
+		}
+		up[i] = b
+	}
+	return u.Hash.Write(up)
+}
+
+func init() {
+	RegisterTransform("upper-test", func(inner hash.Hash) hash.Hash {
+		return upperHash{inner}
+	})
+}
+
+func TestCompoundAlgorithm(t *testing.T) {
+	alg := Algorithm("upper-test+sha256")
+
+	if !alg.Available() {
+		t.Fatal("expected compound algorithm to be available")
+	}
+	if alg.Size() != SHA256.Size() {
+		t.Fatalf("Size() = %d, want %d", alg.Size(), SHA256.Size())
+	}
+
+	dgst := alg.FromString("hello")
+	if dgst.Algorithm() != alg {
+		t.Fatalf("Algorithm() = %v, want %v", dgst.Algorithm(), alg)
+	}
+	if err := dgst.Validate(); err != nil {
+		t.Fatalf("unexpected error validating compound digest: %v", err)
+	}
+
+	if dgst.Encoded() != SHA256.FromString("HELLO").Encoded() {
+		t.Fatalf("transform was not applied: %v != %v", dgst, SHA256.FromString("HELLO"))
+	}
+}
+
+func TestCompoundAlgorithmUnavailableTransform(t *testing.T) {
+	alg := Algorithm("no-such-transform+sha256")
+	if alg.Available() {
+		t.Fatal("expected algorithm with unregistered transform to be unavailable")
+	}
+	if _, err := Parse(alg.String() + ":" + SHA256.FromString("hello").Encoded()); err == nil {
+		t.Fatal("expected error parsing digest with unregistered transform")
+	}
+
+	// Validate and Size are public API in their own right; they must agree
+	// with Available rather than only being exercised indirectly through
+	// Digest.Validate/Parse, which happen to check Available first.
+	encoded := SHA256.FromString("hello").Encoded()
+	if err := alg.Validate(encoded); !errors.Is(err, ErrDigestUnsupported) {
+		t.Fatalf("Validate() error = %v, want %v", err, ErrDigestUnsupported)
+	}
+	if size := alg.Size(); size != 0 {
+		t.Fatalf("Size() = %d, want 0", size)
+	}
+}
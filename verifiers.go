@@ -0,0 +1,80 @@
+// Copyright 2019, 2020 OCI Contributors
+// Copyright 2017 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package digest
+
+import (
+	"hash"
+	"io"
+)
+
+// Verifier presents a general verification interface to be used with message
+// digests and other byte stream verifications. Users instantiate a Verifier
+// from one of the hashing packages to verify a byte stream. Simply write to
+// the verifier and check the boolean return value from Verified.
+type Verifier interface {
+	io.Writer
+
+	// Verified will return true if the content written to Verifier matches
+	// the digest.
+	Verified() bool
+}
+
+type hashVerifier struct {
+	digest Digest
+	hash   hash.Hash
+}
+
+func (hv hashVerifier) Write(p []byte) (n int, err error) {
+	return hv.hash.Write(p)
+}
+
+func (hv hashVerifier) Verified() bool {
+	return hv.digest == NewDigest(hv.digest.Algorithm(), hv.hash)
+}
+
+// sizeVerifier is a hashVerifier that also tracks an expected content
+// length, failing fast on overflow and caching its result once exactly
+// that many bytes have been written.
+type sizeVerifier struct {
+	hashVerifier
+	size     int64
+	written  int64
+	verified bool
+	result   bool
+}
+
+func (sv *sizeVerifier) Write(p []byte) (n int, err error) {
+	if sv.written+int64(len(p)) > sv.size {
+		return 0, ErrDigestSizeMismatch
+	}
+
+	n, err = sv.hashVerifier.Write(p)
+	sv.written += int64(n)
+
+	if err == nil && sv.written == sv.size {
+		sv.result = sv.hashVerifier.Verified()
+		sv.verified = true
+	}
+
+	return n, err
+}
+
+func (sv *sizeVerifier) Verified() bool {
+	if sv.verified {
+		return sv.result
+	}
+	return sv.hashVerifier.Verified()
+}
@@ -0,0 +1,72 @@
+// Copyright 2019, 2020 OCI Contributors
+// Copyright 2017 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package digest
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSizeVerifierExactLength(t *testing.T) {
+	content := []byte("hello world")
+	dgst := FromBytes(content)
+
+	verifier := dgst.SizeVerifier(int64(len(content)))
+	if _, err := verifier.Write(content); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !verifier.Verified() {
+		t.Fatal("expected verifier to be verified")
+	}
+}
+
+func TestSizeVerifierUnderLength(t *testing.T) {
+	content := []byte("hello world")
+	dgst := FromBytes(content)
+
+	verifier := dgst.SizeVerifier(int64(len(content)))
+	if _, err := verifier.Write(content[:len(content)-1]); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if verifier.Verified() {
+		t.Fatal("expected verifier to not be verified before all bytes are written")
+	}
+}
+
+func TestSizeVerifierOverLength(t *testing.T) {
+	content := []byte("hello world")
+	dgst := FromBytes(content)
+
+	verifier := dgst.SizeVerifier(int64(len(content) - 1))
+	if _, err := verifier.Write(content); !errors.Is(err, ErrDigestSizeMismatch) {
+		t.Fatalf("unexpected error: %v, want %v", err, ErrDigestSizeMismatch)
+	}
+}
+
+func TestSizeVerifierIncrementalWrites(t *testing.T) {
+	content := []byte("hello world")
+	dgst := FromBytes(content)
+
+	verifier := dgst.SizeVerifier(int64(len(content)))
+	for i, b := range content {
+		if _, err := verifier.Write([]byte{b}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if want := i == len(content)-1; verifier.Verified() != want {
+			t.Fatalf("after %d bytes, Verified() = %v, want %v", i+1, verifier.Verified(), want)
+		}
+	}
+}